@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strconv"
+
+	opentrace "github.com/opentracing/opentracing-go"
+	otExt "github.com/opentracing/opentracing-go/ext"
+	otl "github.com/opentracing/opentracing-go/log"
+)
+
+// ErrorStackDepth is the maximum number of stack frames SetError logs on a
+// span. Stack capture is disabled when it is zero.
+var ErrorStackDepth = 0
+
+// InjectHTTPRequest writes the propagation headers for span into the
+// request and tags the span with the peer host, port and scheme taken from
+// the request URL.
+// Example usage:
+//
+//	req, err := http.NewRequest("GET", url, nil)
+//	...
+//	if span := opentrace.SpanFromContext(ctx); span != nil {
+//		log.InjectHTTPRequest(span, req)
+//	}
+func InjectHTTPRequest(span opentrace.Span, req *http.Request) error {
+	u := req.URL
+	otExt.PeerHostname.Set(span, u.Hostname())
+	if port, err := strconv.ParseUint(peerPort(u), 10, 16); err == nil {
+		otExt.PeerPort.Set(span, uint16(port))
+	}
+	span.SetTag("peer.scheme", u.Scheme)
+
+	carrier := opentrace.HTTPHeadersCarrier(req.Header)
+	return span.Tracer().Inject(span.Context(), opentrace.HTTPHeaders, carrier)
+}
+
+// peerPort returns the explicit port of u, falling back to the default port
+// of its scheme.
+func peerPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch u.Scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+// SetError tags span with error=true and logs the message and type of err
+// on it. If ErrorStackDepth is non-zero, the caller's stack is logged too.
+// A nil err is ignored.
+func SetError(span opentrace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	otExt.Error.Set(span, true)
+	fields := []otl.Field{
+		otl.String("event", "error"),
+		otl.String("message", err.Error()),
+		otl.String("error.kind", fmt.Sprintf("%T", err)),
+	}
+	if ErrorStackDepth > 0 {
+		fields = append(fields, otl.String("stack", callerStack(1, ErrorStackDepth)))
+	}
+	span.LogFields(fields...)
+}
+
+// callerStack formats at most depth frames of the calling goroutine's
+// stack, skipping the caller of callerStack and skip frames above it.
+func callerStack(skip, depth int) string {
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var buf bytes.Buffer
+	for n > 0 {
+		frame, more := frames.Next()
+		fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
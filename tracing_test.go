@@ -0,0 +1,72 @@
+package log
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestInjectHTTPRequest(t *testing.T) {
+	t.Parallel()
+
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("fetch").(*mocktracer.MockSpan)
+
+	req, err := http.NewRequest("GET", "https://example.com/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InjectHTTPRequest(span, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Header.Get("Mockpfx-Ids-Spanid") == "" {
+		t.Fatal("propagation headers not injected")
+	}
+	if host := span.Tag("peer.hostname"); host != "example.com" {
+		t.Fatalf("wrong peer.hostname: %v", host)
+	}
+	if port := span.Tag("peer.port"); port != uint16(443) {
+		t.Fatalf("wrong peer.port: %v", port)
+	}
+	if scheme := span.Tag("peer.scheme"); scheme != "https" {
+		t.Fatalf("wrong peer.scheme: %v", scheme)
+	}
+}
+
+func TestSetError(t *testing.T) {
+	ErrorStackDepth = 4
+	defer func() { ErrorStackDepth = 0 }()
+
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("fetch").(*mocktracer.MockSpan)
+
+	SetError(span, errors.New("boom"))
+
+	if span.Tag("error") != true {
+		t.Fatal("error tag not set")
+	}
+	logs := span.Logs()
+	if len(logs) != 1 {
+		t.Fatalf("expected one log record, got %d", len(logs))
+	}
+	fields := map[string]string{}
+	for _, f := range logs[0].Fields {
+		fields[f.Key] = f.ValueString
+	}
+	if fields["message"] != "boom" {
+		t.Fatalf("wrong message: %q", fields["message"])
+	}
+	if fields["error.kind"] != "*errors.errorString" {
+		t.Fatalf("wrong error.kind: %q", fields["error.kind"])
+	}
+	if !strings.Contains(fields["stack"], "TestSetError") {
+		t.Fatalf("stack does not start at the caller:\n%s", fields["stack"])
+	}
+	if strings.Contains(fields["stack"], ".SetError\n") {
+		t.Fatalf("stack includes SetError itself:\n%s", fields["stack"])
+	}
+}